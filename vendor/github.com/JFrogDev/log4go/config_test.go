@@ -0,0 +1,158 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import "testing"
+
+func TestLoadConfigurationBytesJSON(t *testing.T) {
+	path := tempLogPath(t)
+	cfg := []byte(`{
+		"filter": [
+			{
+				"enabled": true,
+				"tag": "file",
+				"type": "file",
+				"level": "DEBUG",
+				"property": [
+					{"name": "filename", "value": "` + path + `"},
+					{"name": "maxlines", "value": "100"}
+				]
+			},
+			{
+				"enabled": false,
+				"tag": "disabled",
+				"type": "file",
+				"level": "DEBUG",
+				"property": [{"name": "filename", "value": "` + path + `.disabled"}]
+			}
+		]
+	}`)
+
+	log, err := LoadConfigurationBytes(cfg, "json")
+	if err != nil {
+		t.Fatalf("LoadConfigurationBytes: %v", err)
+	}
+	defer log.Close()
+
+	if len(log.Filters) != 1 {
+		t.Fatalf("expected 1 enabled filter, got %d", len(log.Filters))
+	}
+	if _, ok := log.Filters["file"]; !ok {
+		t.Fatalf("expected a filter tagged %q", "file")
+	}
+}
+
+func TestLoadConfigurationBytesXML(t *testing.T) {
+	path := tempLogPath(t)
+	cfg := []byte(`<logging>
+		<filter enabled="true">
+			<tag>file</tag>
+			<type>file</type>
+			<level>INFO</level>
+			<property name="filename">` + path + `</property>
+		</filter>
+	</logging>`)
+
+	log, err := LoadConfigurationBytes(cfg, "xml")
+	if err != nil {
+		t.Fatalf("LoadConfigurationBytes: %v", err)
+	}
+	defer log.Close()
+
+	if len(log.Filters) != 1 {
+		t.Fatalf("expected 1 enabled filter, got %d", len(log.Filters))
+	}
+}
+
+func TestLoadConfigurationBytesUnknownType(t *testing.T) {
+	cfg := []byte(`{"filter": [{"enabled": true, "tag": "x", "type": "bogus", "level": "INFO"}]}`)
+
+	if _, err := LoadConfigurationBytes(cfg, "json"); err == nil {
+		t.Fatal("expected an error for an unsupported filter type")
+	}
+}
+
+func TestLoadConfigurationBytesConsole(t *testing.T) {
+	cfg := []byte(`{"filter": [{"enabled": true, "tag": "stdout", "type": "console", "level": "INFO"}]}`)
+
+	log, err := LoadConfigurationBytes(cfg, "json")
+	if err != nil {
+		t.Fatalf("LoadConfigurationBytes: %v", err)
+	}
+	defer log.Close()
+
+	if _, ok := log.Filters["stdout"]; !ok {
+		t.Fatalf("expected a filter tagged %q", "stdout")
+	}
+}
+
+func TestLoadConfigurationBytesFormatPropertyRejected(t *testing.T) {
+	cfg := []byte(`{
+		"filter": [
+			{
+				"enabled": true,
+				"tag": "stdout",
+				"type": "console",
+				"level": "INFO",
+				"property": [{"name": "format", "value": "%d %m"}]
+			}
+		]
+	}`)
+
+	if _, err := LoadConfigurationBytes(cfg, "json"); err == nil {
+		t.Fatal("expected an error for an unsupported \"format\" property")
+	}
+}
+
+func TestLoggerReloadBytesAddsRemovesAndRebuildsFilters(t *testing.T) {
+	path := tempLogPath(t)
+	log, err := LoadConfigurationBytes([]byte(`{
+		"filter": [
+			{"enabled": true, "tag": "stdout", "type": "console", "level": "INFO"},
+			{
+				"enabled": true, "tag": "file", "type": "file", "level": "DEBUG",
+				"property": [{"name": "filename", "value": "`+path+`"}]
+			}
+		]
+	}`), "json")
+	if err != nil {
+		t.Fatalf("LoadConfigurationBytes: %v", err)
+	}
+	defer log.Close()
+
+	oldFile := log.Filters["file"].LogWriter
+
+	// Drop "stdout", keep "file" (rebuilt with a new level), add "added".
+	err = log.ReloadBytes([]byte(`{
+		"filter": [
+			{
+				"enabled": true, "tag": "file", "type": "file", "level": "ERROR",
+				"property": [{"name": "filename", "value": "`+path+`"}]
+			},
+			{"enabled": true, "tag": "added", "type": "console", "level": "INFO"}
+		]
+	}`), "json")
+	if err != nil {
+		t.Fatalf("ReloadBytes: %v", err)
+	}
+
+	if len(log.Filters) != 2 {
+		t.Fatalf("expected 2 filters after reload, got %d: %v", len(log.Filters), log.Filters)
+	}
+	if _, ok := log.Filters["stdout"]; ok {
+		t.Fatal("expected \"stdout\" to be removed by reload")
+	}
+	if _, ok := log.Filters["added"]; !ok {
+		t.Fatal("expected \"added\" to be registered by reload")
+	}
+	file, ok := log.Filters["file"]
+	if !ok {
+		t.Fatal("expected \"file\" to survive reload")
+	}
+	if file.Level != ERROR {
+		t.Fatalf("expected \"file\"'s level to be updated to ERROR, got %v", file.Level)
+	}
+	if file.LogWriter == oldFile {
+		t.Fatal("expected \"file\"'s LogWriter to be rebuilt, not reused")
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// ConsoleLogWriter sends output to an io.Writer (os.Stdout by default) as
+// "[time] [level] message", one line per LogRecord.  It does not display
+// the source of the message, unlike RotatingFileLogWriter.
+type ConsoleLogWriter struct {
+	out io.Writer
+}
+
+// NewConsoleLogWriter creates a ConsoleLogWriter that writes to os.Stdout.
+func NewConsoleLogWriter() *ConsoleLogWriter {
+	return &ConsoleLogWriter{out: os.Stdout}
+}
+
+// LogWrite formats rec and writes it to the writer's output synchronously.
+func (w *ConsoleLogWriter) LogWrite(rec *LogRecord) {
+	fmt.Fprintf(w.out, "[%s] [%s] %s\n", rec.Created.Format("2006/01/02 15:04:05"), rec.Level, rec.Message)
+}
+
+// Close is a no-op: ConsoleLogWriter never buffers anything to flush.
+func (w *ConsoleLogWriter) Close() {}
@@ -0,0 +1,30 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsoleLogWriterOmitsSource(t *testing.T) {
+	var buf bytes.Buffer
+	w := &ConsoleLogWriter{out: &buf}
+
+	w.LogWrite(&LogRecord{
+		Level:   INFO,
+		Created: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Source:  "pkg.Func:42",
+		Message: "hello",
+	})
+
+	line := buf.String()
+	if !strings.Contains(line, "INFO") || !strings.Contains(line, "hello") {
+		t.Fatalf("unexpected line: %q", line)
+	}
+	if strings.Contains(line, "pkg.Func") {
+		t.Fatalf("expected ConsoleLogWriter to omit the source, got: %q", line)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import "sync/atomic"
+
+// OverflowPolicy controls what a Filter does with a LogRecord when its
+// queue (sized by LogBufferLength) is already full.
+type OverflowPolicy int
+
+const (
+	// DropNewest discards the record that was about to be enqueued. This
+	// is the default policy.
+	DropNewest OverflowPolicy = iota
+	// DropOldest discards the oldest queued record to make room for the
+	// new one.
+	DropOldest
+	// Block waits for room in the queue, exerting backpressure on the
+	// caller. This restores the pre-pipeline blocking behavior for
+	// filters that must never lose a record.
+	Block
+)
+
+// FilterStats is a point-in-time snapshot of a Filter's delivery counters,
+// returned by Logger.Stats.
+type FilterStats struct {
+	Enqueued int64 // records accepted onto the filter's queue
+	Written  int64 // records handed to the underlying LogWriter
+	Dropped  int64 // records discarded by the overflow policy
+}
+
+// newFilter builds a Filter around writer and starts the goroutine that
+// drains its queue into writer.LogWrite.
+func newFilter(lvl Level, writer LogWriter) *Filter {
+	filt := &Filter{
+		Level:     lvl,
+		LogWriter: writer,
+		queue:     make(chan *LogRecord, LogBufferLength),
+		done:      make(chan struct{}),
+	}
+	go filt.run()
+	return filt
+}
+
+func (f *Filter) run() {
+	defer close(f.done)
+	for rec := range f.queue {
+		f.LogWrite(rec)
+		atomic.AddInt64(&f.written, 1)
+	}
+}
+
+// send enqueues rec according to f.Policy, never blocking the caller unless
+// the policy is Block.
+func (f *Filter) send(rec *LogRecord) {
+	switch f.Policy {
+	case Block:
+		f.queue <- rec
+		atomic.AddInt64(&f.enqueued, 1)
+
+	case DropOldest:
+		select {
+		case f.queue <- rec:
+			atomic.AddInt64(&f.enqueued, 1)
+			return
+		default:
+		}
+		select {
+		case <-f.queue:
+			atomic.AddInt64(&f.dropped, 1)
+		default:
+		}
+		select {
+		case f.queue <- rec:
+			atomic.AddInt64(&f.enqueued, 1)
+		default:
+			atomic.AddInt64(&f.dropped, 1)
+		}
+
+	default: // DropNewest
+		select {
+		case f.queue <- rec:
+			atomic.AddInt64(&f.enqueued, 1)
+		default:
+			atomic.AddInt64(&f.dropped, 1)
+		}
+	}
+}
+
+// Close stops this filter's dispatch goroutine once its queue has fully
+// drained, then closes the underlying LogWriter.  It shadows the embedded
+// LogWriter's Close so Logger.Close and Logger.RemoveFilter get
+// flush-then-stop semantics without change.
+func (f *Filter) Close() {
+	close(f.queue)
+	<-f.done
+	f.LogWriter.Close()
+}
+
+// SetFilterPolicy sets the OverflowPolicy used when the named filter's
+// queue is full.  It has no effect if name is not currently registered.
+// Like AddFilter, this function should not be called concurrently with
+// logging. Returns the logger for chaining.
+func (log *Logger) SetFilterPolicy(name string, policy OverflowPolicy) *Logger {
+	if filt, ok := log.Filters[name]; ok {
+		filt.Policy = policy
+	}
+	return log
+}
+
+// Stats returns a snapshot of each filter's delivery counters, keyed by
+// filter name, so operators can detect log loss from a full queue.
+func (log *Logger) Stats() map[string]FilterStats {
+	log.acquireLock()
+	defer log.releaseLock()
+
+	stats := make(map[string]FilterStats, len(log.Filters))
+	for name, filt := range log.Filters {
+		stats[name] = FilterStats{
+			Enqueued: atomic.LoadInt64(&filt.enqueued),
+			Written:  atomic.LoadInt64(&filt.written),
+			Dropped:  atomic.LoadInt64(&filt.dropped),
+		}
+	}
+	return stats
+}
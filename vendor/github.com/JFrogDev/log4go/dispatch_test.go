@@ -0,0 +1,89 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingLogWriter blocks every LogWrite until released, so tests can
+// observe backpressure/drop behavior on a full queue.
+type blockingLogWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	got     []*LogRecord
+}
+
+func newBlockingLogWriter() *blockingLogWriter {
+	return &blockingLogWriter{release: make(chan struct{})}
+}
+
+func (w *blockingLogWriter) LogWrite(rec *LogRecord) {
+	<-w.release
+	w.mu.Lock()
+	w.got = append(w.got, rec)
+	w.mu.Unlock()
+}
+
+func (w *blockingLogWriter) Close() {}
+
+func TestLoggerBlockPolicyDeliversEveryRecord(t *testing.T) {
+	old := LogBufferLength
+	LogBufferLength = 2
+	defer func() { LogBufferLength = old }()
+
+	w := newBlockingLogWriter()
+	log := NewLogger()
+	log.AddFilter("block", INFO, w)
+	log.SetFilterPolicy("block", Block)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			log.Info("message %d", i)
+		}
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	close(w.release)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Block policy should not drop records, but the sends never completed")
+	}
+
+	log.Close()
+
+	w.mu.Lock()
+	got := len(w.got)
+	w.mu.Unlock()
+	if got != 10 {
+		t.Fatalf("expected all 10 records to be written under Block policy, got %d", got)
+	}
+}
+
+func TestLoggerStatsCountsDropped(t *testing.T) {
+	old := LogBufferLength
+	LogBufferLength = 1
+	defer func() { LogBufferLength = old }()
+
+	w := newBlockingLogWriter()
+	log := NewLogger()
+	log.AddFilter("block", INFO, w)
+
+	for i := 0; i < 5; i++ {
+		log.Info("message %d", i)
+	}
+
+	stats := log.Stats()["block"]
+	if stats.Dropped == 0 {
+		t.Fatalf("expected some records to be dropped with a full DropNewest queue, got %+v", stats)
+	}
+
+	close(w.release)
+	log.Close()
+}
@@ -0,0 +1,110 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import "time"
+
+// fieldType identifies which member of a Field actually holds its value, so
+// that LogWriters can decode a Field without a type switch on interface{}.
+type fieldType int
+
+const (
+	unknownType fieldType = iota
+	int64Type
+	float64Type
+	stringType
+	boolType
+	timeType
+	durationType
+	errorType
+	anyType
+)
+
+// A Field is a strongly typed key/value pair attached to a LogRecord by the
+// *w logging methods (Debugw, Infow, ...) and consumed by structured
+// LogWriters such as JSONLogWriter and LogfmtLogWriter.
+type Field struct {
+	Key   string
+	typ   fieldType
+	ival  int64
+	sval  string
+	iface interface{}
+}
+
+// Int64 creates a Field carrying an int64 value.
+func Int64(key string, val int64) Field {
+	return Field{Key: key, typ: int64Type, ival: val}
+}
+
+// Int creates a Field carrying an int value.
+func Int(key string, val int) Field {
+	return Int64(key, int64(val))
+}
+
+// Float64 creates a Field carrying a float64 value.
+func Float64(key string, val float64) Field {
+	return Field{Key: key, typ: float64Type, iface: val}
+}
+
+// String creates a Field carrying a string value.
+func String(key, val string) Field {
+	return Field{Key: key, typ: stringType, sval: val}
+}
+
+// Bool creates a Field carrying a bool value.
+func Bool(key string, val bool) Field {
+	var ival int64
+	if val {
+		ival = 1
+	}
+	return Field{Key: key, typ: boolType, ival: ival}
+}
+
+// Time creates a Field carrying a time.Time value.
+func Time(key string, val time.Time) Field {
+	return Field{Key: key, typ: timeType, iface: val}
+}
+
+// Duration creates a Field carrying a time.Duration value.
+func Duration(key string, val time.Duration) Field {
+	return Field{Key: key, typ: durationType, ival: int64(val)}
+}
+
+// Err creates a Field named "error" carrying err's message.  A nil err
+// produces an empty string, so callers can unconditionally pass Err(err).
+func Err(err error) Field {
+	if err == nil {
+		return Field{Key: "error", typ: errorType}
+	}
+	return Field{Key: "error", typ: errorType, sval: err.Error()}
+}
+
+// Any creates a Field carrying an arbitrary value, for cases not covered by
+// the typed constructors above.  LogWriters render it with its natural
+// formatting (e.g. %v or json.Marshal).
+func Any(key string, val interface{}) Field {
+	return Field{Key: key, typ: anyType, iface: val}
+}
+
+// Value unboxes the Field's value as an interface{}, suitable for generic
+// consumers such as JSONLogWriter and LogfmtLogWriter.
+func (f Field) Value() interface{} {
+	switch f.typ {
+	case int64Type:
+		return f.ival
+	case float64Type:
+		return f.iface
+	case stringType:
+		return f.sval
+	case boolType:
+		return f.ival != 0
+	case timeType:
+		return f.iface
+	case durationType:
+		return time.Duration(f.ival)
+	case errorType:
+		return f.sval
+	default:
+		return f.iface
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFieldConstructorsValue(t *testing.T) {
+	when := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		f    Field
+		want interface{}
+	}{
+		{"Int64", Int64("n", 42), int64(42)},
+		{"Int", Int("n", 42), int64(42)},
+		{"Float64", Float64("f", 3.5), 3.5},
+		{"String", String("s", "hi"), "hi"},
+		{"Bool", Bool("b", true), true},
+		{"Time", Time("t", when), when},
+		{"Duration", Duration("d", 2*time.Second), 2 * time.Second},
+		{"Err", Err(errors.New("boom")), "boom"},
+		{"ErrNil", Err(nil), ""},
+		{"Any", Any("a", []int{1, 2}), []int{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.name == "Any" {
+				// Slices aren't comparable with ==; Any just round-trips
+				// whatever was passed in, so a length check is enough here.
+				if got := tt.f.Value().([]int); len(got) != 2 {
+					t.Fatalf("Value() = %#v, want length 2", got)
+				}
+				return
+			}
+			if got := tt.f.Value(); got != tt.want {
+				t.Fatalf("Value() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldErrKeyIsError(t *testing.T) {
+	if f := Err(errors.New("boom")); f.Key != "error" {
+		t.Fatalf("Err: Key = %q, want %q", f.Key, "error")
+	}
+}
+
+func TestLoggerWithPrependsFields(t *testing.T) {
+	log := NewLogger()
+	child := log.With(String("request_id", "abc")).With(Int("attempt", 1))
+
+	if len(child.context) != 2 {
+		t.Fatalf("expected 2 context fields after two With calls, got %d", len(child.context))
+	}
+	if child.context[0].Key != "request_id" || child.context[1].Key != "attempt" {
+		t.Fatalf("expected context fields in call order, got %+v", child.context)
+	}
+
+	// The parent's context must be unaffected by the child's.
+	if len(log.context) != 0 {
+		t.Fatalf("expected parent Logger's context to stay empty, got %+v", log.context)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// This log writer sends output to an io.Writer as one JSON object per
+// LogRecord, suitable for ingestion by log collectors that expect
+// newline-delimited JSON.  Buffering and overflow handling are the
+// dispatching Filter's job (see dispatch.go); LogWrite writes synchronously.
+type JSONLogWriter struct {
+	out io.Writer
+	enc *json.Encoder
+}
+
+// jsonRecord is the on-the-wire shape of a LogRecord: the fixed fields plus
+// any structured Fields flattened alongside them.
+type jsonRecord map[string]interface{}
+
+// NewJSONLogWriter creates a JSONLogWriter that writes to out, which may be
+// os.Stdout, a file opened for append, or any other io.Writer.
+func NewJSONLogWriter(out io.Writer) *JSONLogWriter {
+	return &JSONLogWriter{out: out, enc: json.NewEncoder(out)}
+}
+
+// LogWrite encodes rec as a JSON object and writes it to the writer's
+// output.
+func (w *JSONLogWriter) LogWrite(rec *LogRecord) {
+	obj := jsonRecord{
+		"level":  rec.Level.String(),
+		"ts":     rec.Created,
+		"source": rec.Source,
+		"msg":    rec.Message,
+	}
+	for _, f := range rec.Fields {
+		obj[f.Key] = f.Value()
+	}
+	if err := w.enc.Encode(obj); err != nil {
+		os.Stderr.WriteString("JSONLogWriter: " + err.Error() + "\n")
+	}
+}
+
+// Close is a no-op: JSONLogWriter writes every record synchronously, so
+// there is nothing left to flush.
+func (w *JSONLogWriter) Close() {}
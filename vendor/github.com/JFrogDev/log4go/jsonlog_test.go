@@ -0,0 +1,50 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestJSONLogWriterEmitsRecordWithFields(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONLogWriter(&buf)
+
+	w.LogWrite(&LogRecord{
+		Level:   INFO,
+		Created: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Source:  "test",
+		Message: "hello",
+		Fields:  []Field{String("request_id", "abc"), Int("attempt", 2)},
+	})
+	w.Close()
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", buf.String(), err)
+	}
+	if got["msg"] != "hello" || got["level"] != "INFO" || got["source"] != "test" {
+		t.Fatalf("unexpected fixed fields: %v", got)
+	}
+	if got["request_id"] != "abc" || got["attempt"] != float64(2) {
+		t.Fatalf("unexpected structured fields: %v", got)
+	}
+}
+
+func TestJSONLogWriterWritesSynchronously(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONLogWriter(&buf)
+
+	for i := 0; i < 5; i++ {
+		w.LogWrite(&LogRecord{Level: INFO, Created: time.Now(), Source: "test", Message: "msg"})
+	}
+
+	// LogWrite no longer hands off to a background goroutine, so all 5
+	// records must already be in buf with no Close required.
+	if got := bytes.Count(buf.Bytes(), []byte("\n")); got != 5 {
+		t.Fatalf("expected 5 records written synchronously, got %d", got)
+	}
+}
@@ -37,9 +37,6 @@
 //   behind-the-scenes, and the LogWrite method no longer has return values.
 //
 // Future work: (please let me know if you think I should work on any of these particularly)
-// - Log file rotation
-// - Logging configuration files ala log4j
-// - Have the ability to remove filters?
 // - Have GetInfoChannel, GetDebugChannel, etc return a chan string that allows
 //   for another method of logging
 // - Add an XML filter type
@@ -106,6 +103,7 @@ type LogRecord struct {
 	Created time.Time // The time at which the log message was created (nanoseconds)
 	Source  string    // The message source
 	Message string    // The log message
+	Fields  []Field   // Structured key/value pairs attached via the *w logging methods
 }
 
 /****** LogWriter ******/
@@ -123,10 +121,20 @@ type LogWriter interface {
 /****** Logger ******/
 
 // A Filter represents the log level below which no log records are written to
-// the associated LogWriter.
+// the associated LogWriter.  Records that pass the level check are queued
+// and written by a dedicated goroutine (see newFilter), so a slow LogWriter
+// never blocks the caller or other filters, unless the filter's Policy is
+// Block and its queue is full.
 type Filter struct {
 	Level Level
 	LogWriter
+
+	Policy OverflowPolicy // what to do when queue is full; see SetFilterPolicy
+
+	queue chan *LogRecord
+	done  chan struct{}
+
+	enqueued, written, dropped int64 // access via sync/atomic; see Stats
 }
 
 type Filters map[string]*Filter
@@ -135,7 +143,8 @@ type Filters map[string]*Filter
 // written.
 type Logger struct {
 	Filters
-	sync.RWMutex
+	*sync.RWMutex
+	context []Field // Fields prepended to every record logged through this Logger, see With
 }
 
 func (log *Logger) acquireLock() {
@@ -156,7 +165,7 @@ func (log *Logger) releaseWriteLock() {
 
 // Create a new logger.
 func NewLogger() *Logger {
-	return &Logger{Filters: make(Filters), RWMutex: sync.RWMutex{}}
+	return &Logger{Filters: make(Filters), RWMutex: &sync.RWMutex{}}
 }
 
 // Create a new logger with a "stdout" filter configured to send log messages at
@@ -172,7 +181,7 @@ func NewConsoleLogger(lvl Level) *Logger {
 // or above lvl to standard output.
 func NewDefaultLogger(lvl Level) *Logger {
 	logger := NewLogger()
-	logger.Filters["stdout"] = &Filter{lvl, NewConsoleLogWriter()}
+	logger.Filters["stdout"] = newFilter(lvl, NewConsoleLogWriter())
 	return logger
 }
 
@@ -195,10 +204,34 @@ func (log *Logger) Close() {
 // higher.  This function should not be called from multiple goroutines.
 // Returns the logger for chaining.
 func (log *Logger) AddFilter(name string, lvl Level, writer LogWriter) *Logger {
-	log.Filters[name] = &Filter{lvl, writer}
+	log.Filters[name] = newFilter(lvl, writer)
 	return log
 }
 
+// RemoveFilter removes and closes the named filter, if present.  This lets a
+// running program reconfigure itself; see Logger.Reload, which uses it to
+// drop filters no longer present in a freshly reloaded config file.
+func (log *Logger) RemoveFilter(name string) {
+	log.acquireWriteLock()
+	defer log.releaseWriteLock()
+
+	if filt, ok := log.Filters[name]; ok {
+		filt.Close()
+		delete(log.Filters, name)
+	}
+}
+
+// With returns a child Logger that prepends fields to every record logged
+// through it from then on, in addition to whatever fields are passed to the
+// individual *w call.  The child shares the parent's filters, so adding or
+// removing a filter on either one is visible to both.
+func (log *Logger) With(fields ...Field) *Logger {
+	context := make([]Field, 0, len(log.context)+len(fields))
+	context = append(context, log.context...)
+	context = append(context, fields...)
+	return &Logger{Filters: log.Filters, RWMutex: log.RWMutex, context: context}
+}
+
 func (log *Logger) shouldSkip(lvl Level) bool {
 	// Determine if any logging will be done
 	skip := true
@@ -213,12 +246,15 @@ func (log *Logger) shouldSkip(lvl Level) bool {
 }
 
 func (log *Logger) dispatchLogsForLogRecord(lvl Level, rec *LogRecord) {
-	// Dispatch the logs
+	// Dispatch the logs: a send onto each filter's own queue, per that
+	// filter's OverflowPolicy.  Only a filter whose policy is Block can wait
+	// here for room, stalling the caller and the filters after it; under
+	// the other policies one slow LogWriter holds up nothing else.
 	for _, filt := range log.Filters {
 		if lvl < filt.Level {
 			continue
 		}
-		filt.LogWrite(rec)
+		filt.send(rec)
 	}
 }
 
@@ -234,6 +270,32 @@ func (log *Logger) dispatchLogsForSourceAndMessage(lvl Level, source, message st
 	log.dispatchLogsForLogRecord(lvl, rec)
 }
 
+// Send a structured log message internally, merging the Logger's context
+// fields (see With) ahead of the fields passed by the caller.
+func (log *Logger) intLogw(lvl Level, msg string, fields []Field) {
+	log.acquireLock()
+	defer log.releaseLock()
+	if log.shouldSkip(lvl) {
+		return
+	}
+
+	// Determine caller func
+	pc, _, lineno, ok := runtime.Caller(2)
+	src := ""
+	if ok {
+		src = fmt.Sprintf("%s:%d", runtime.FuncForPC(pc).Name(), lineno)
+	}
+
+	rec := &LogRecord{
+		Level:   lvl,
+		Created: time.Now(),
+		Source:  src,
+		Message: msg,
+		Fields:  append(append([]Field{}, log.context...), fields...),
+	}
+	log.dispatchLogsForLogRecord(lvl, rec)
+}
+
 /******* Logging *******/
 // Send a formatted log message internally
 func (log *Logger) intLogf(lvl Level, format string, args ...interface{}) {
@@ -400,4 +462,36 @@ func (log *Logger) Error(arg0 interface{}, args ...interface{}) error {
 func (log *Logger) Critical(arg0 interface{}, args ...interface{}) error {
 	const lvl = CRITICAL
 	return log.overWarning(lvl, arg0, args...)
+}
+
+// Debugw logs a structured message at the debug log level.  msg is used
+// verbatim (it is not a format string); fields are attached to the
+// LogRecord for LogWriters that understand them, such as JSONLogWriter and
+// LogfmtLogWriter.
+func (log *Logger) Debugw(msg string, fields ...Field) {
+	log.intLogw(DEBUG, msg, fields)
+}
+
+// Infow logs a structured message at the info log level.
+// See Debugw for an explanation of the arguments.
+func (log *Logger) Infow(msg string, fields ...Field) {
+	log.intLogw(INFO, msg, fields)
+}
+
+// Warnw logs a structured message at the warning log level.
+// See Debugw for an explanation of the arguments.
+func (log *Logger) Warnw(msg string, fields ...Field) {
+	log.intLogw(WARNING, msg, fields)
+}
+
+// Errorw logs a structured message at the error log level.
+// See Debugw for an explanation of the arguments.
+func (log *Logger) Errorw(msg string, fields ...Field) {
+	log.intLogw(ERROR, msg, fields)
+}
+
+// Criticalw logs a structured message at the critical log level.
+// See Debugw for an explanation of the arguments.
+func (log *Logger) Criticalw(msg string, fields ...Field) {
+	log.intLogw(CRITICAL, msg, fields)
 }
\ No newline at end of file
@@ -0,0 +1,61 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// This log writer sends output to an io.Writer as logfmt-style
+// space-separated key=value pairs (level=INFO ts=... source=... msg="..."
+// plus any structured Fields), one line per LogRecord.  Buffering and
+// overflow handling are the dispatching Filter's job (see dispatch.go);
+// LogWrite writes synchronously.
+type LogfmtLogWriter struct {
+	out io.Writer
+}
+
+// NewLogfmtLogWriter creates a LogfmtLogWriter that writes to out.
+func NewLogfmtLogWriter(out io.Writer) *LogfmtLogWriter {
+	return &LogfmtLogWriter{out: out}
+}
+
+// LogWrite formats rec as a logfmt line and writes it to the writer's
+// output.
+func (w *LogfmtLogWriter) LogWrite(rec *LogRecord) {
+	var line strings.Builder
+	writePair(&line, "level", rec.Level.String())
+	writePair(&line, "ts", rec.Created.Format(time.RFC3339Nano))
+	writePair(&line, "source", rec.Source)
+	writePair(&line, "msg", rec.Message)
+	for _, f := range rec.Fields {
+		writePair(&line, f.Key, f.Value())
+	}
+	line.WriteByte('\n')
+	io.WriteString(w.out, line.String())
+}
+
+// writePair appends a space-separated key=value pair to line, quoting the
+// value with strconv.Quote whenever it contains a space, tab, line break,
+// double quote or '='.
+func writePair(line *strings.Builder, key string, val interface{}) {
+	if line.Len() > 0 {
+		line.WriteByte(' ')
+	}
+	line.WriteString(key)
+	line.WriteByte('=')
+
+	s := fmt.Sprint(val)
+	if strings.ContainsAny(s, " \t\r\n\"=") {
+		s = strconv.Quote(s)
+	}
+	line.WriteString(s)
+}
+
+// Close is a no-op: LogfmtLogWriter writes every record synchronously, so
+// there is nothing left to flush.
+func (w *LogfmtLogWriter) Close() {}
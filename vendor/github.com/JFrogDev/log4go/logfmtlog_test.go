@@ -0,0 +1,50 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogfmtLogWriterEmitsQuotedPairs(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewLogfmtLogWriter(&buf)
+
+	w.LogWrite(&LogRecord{
+		Level:   INFO,
+		Created: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Source:  "test",
+		Message: "hello world",
+		Fields:  []Field{String("request_id", "abc")},
+	})
+	w.Close()
+
+	line := buf.String()
+	if !strings.Contains(line, `level=INFO`) || !strings.Contains(line, `source=test`) {
+		t.Fatalf("unexpected fixed fields in line: %q", line)
+	}
+	if !strings.Contains(line, `msg="hello world"`) {
+		t.Fatalf("expected msg value containing a space to be quoted, got: %q", line)
+	}
+	if !strings.Contains(line, `request_id=abc`) {
+		t.Fatalf("expected structured field in line: %q", line)
+	}
+}
+
+func TestLogfmtLogWriterWritesSynchronously(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewLogfmtLogWriter(&buf)
+
+	for i := 0; i < 5; i++ {
+		w.LogWrite(&LogRecord{Level: INFO, Created: time.Now(), Source: "test", Message: "msg"})
+	}
+
+	// LogWrite no longer hands off to a background goroutine, so all 5
+	// lines must already be in buf with no Close required.
+	if got := strings.Count(buf.String(), "\n"); got != 5 {
+		t.Fatalf("expected 5 lines written synchronously, got %d", got)
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RotatingFileLogWriter writes formatted LogRecords to a file on disk,
+// rotating it out from under itself when any of the configured triggers
+// (size, daily, line count) is hit.  Rotated files are named
+// "<path>.<YYYY-MM-DD>.<NNN>" and optionally gzipped; SetMaxBackups prunes
+// the oldest ones so the log directory does not grow without bound.
+// Buffering and overflow handling are the dispatching Filter's job (see
+// dispatch.go); LogWrite writes synchronously, guarded by mu against
+// concurrent Set* calls.
+type RotatingFileLogWriter struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+
+	rotateSize  int64
+	rotateDaily bool
+	rotateLines int
+	maxBackups  int
+	compress    bool
+
+	curSize  int64
+	curLines int
+	curDay   string
+
+	now func() time.Time // overridden in tests
+}
+
+// NewRotatingFileLogWriter creates a RotatingFileLogWriter that appends to
+// (or creates) the file at path.  Rotation is disabled until one of the
+// Set* methods below is called.
+func NewRotatingFileLogWriter(path string) *RotatingFileLogWriter {
+	w := &RotatingFileLogWriter{
+		path: path,
+		now:  time.Now,
+	}
+
+	if err := w.open(); err != nil {
+		os.Stderr.WriteString("RotatingFileLogWriter(" + path + "): " + err.Error() + "\n")
+	}
+
+	return w
+}
+
+// SetRotateSize rotates the file once it exceeds the given number of bytes.
+// A value of 0 disables the size trigger.  Returns w for chaining.
+func (w *RotatingFileLogWriter) SetRotateSize(bytes int64) *RotatingFileLogWriter {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.rotateSize = bytes
+	return w
+}
+
+// SetRotateDaily rotates the file at the local-midnight boundary, based on
+// each LogRecord's Created time.  Returns w for chaining.
+func (w *RotatingFileLogWriter) SetRotateDaily(daily bool) *RotatingFileLogWriter {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.rotateDaily = daily
+	return w
+}
+
+// SetRotateLines rotates the file once it has accumulated n lines.  A value
+// of 0 disables the line-count trigger.  Returns w for chaining.
+func (w *RotatingFileLogWriter) SetRotateLines(n int) *RotatingFileLogWriter {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.rotateLines = n
+	return w
+}
+
+// SetMaxBackups caps the number of rotated files kept alongside the active
+// log file; the oldest are deleted once the cap is exceeded.  A value of 0
+// means unlimited.  Returns w for chaining.
+func (w *RotatingFileLogWriter) SetMaxBackups(n int) *RotatingFileLogWriter {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.maxBackups = n
+	return w
+}
+
+// SetCompressBackups gzips rotated files in the background so log writes
+// never block on compression.  Returns w for chaining.
+func (w *RotatingFileLogWriter) SetCompressBackups(compress bool) *RotatingFileLogWriter {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.compress = compress
+	return w
+}
+
+func (w *RotatingFileLogWriter) write(rec *LogRecord) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return
+	}
+
+	day := rec.Created.Format("2006-01-02")
+	if w.rotateDaily && w.curDay != "" && day != w.curDay {
+		w.rotate()
+	}
+	w.curDay = day
+
+	line := fmt.Sprintf("[%s] [%s] (%s) %s\n", rec.Created.Format("2006/01/02 15:04:05"), rec.Level, rec.Source, rec.Message)
+	n, err := io.WriteString(w.file, line)
+	if err != nil {
+		os.Stderr.WriteString("RotatingFileLogWriter: " + err.Error() + "\n")
+		return
+	}
+	w.curSize += int64(n)
+	w.curLines++
+
+	if (w.rotateSize > 0 && w.curSize > w.rotateSize) || (w.rotateLines > 0 && w.curLines >= w.rotateLines) {
+		w.rotate()
+	}
+}
+
+// rotate closes the active file, renames it to a dated backup, reopens the
+// original path, and prunes/compresses backups.  Callers must hold w.mu.
+func (w *RotatingFileLogWriter) rotate() {
+	if w.file != nil {
+		w.file.Close()
+		w.file = nil
+	}
+
+	backup := w.nextBackupName()
+	err := os.Rename(w.path, backup)
+	if err == nil && w.compress {
+		go compressBackup(backup)
+	} else if err != nil && !os.IsNotExist(err) {
+		os.Stderr.WriteString("RotatingFileLogWriter: rotate: " + err.Error() + "\n")
+	}
+
+	if err := w.open(); err != nil {
+		os.Stderr.WriteString("RotatingFileLogWriter: reopen: " + err.Error() + "\n")
+		return
+	}
+
+	header := fmt.Sprintf("[%s] [INFO] (log4go) log file rotated\n", w.now().Format("2006/01/02 15:04:05"))
+	io.WriteString(w.file, header)
+	w.curSize = int64(len(header))
+	w.curLines = 1
+
+	w.pruneBackups()
+}
+
+// open creates or appends to the active log file.
+func (w *RotatingFileLogWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if fi, err := f.Stat(); err == nil {
+		w.curSize = fi.Size()
+	}
+	w.file = f
+	return nil
+}
+
+// nextBackupName returns path.YYYY-MM-DD.NNN for today, where NNN is one
+// past the highest number already on disk for today (as either a plain or
+// gzipped backup), so a number freed by pruneBackups is never reused.
+func (w *RotatingFileLogWriter) nextBackupName() string {
+	prefix := fmt.Sprintf("%s.%s.", w.path, w.now().Format("2006-01-02"))
+	next := 1
+	matches, _ := filepath.Glob(prefix + "*")
+	for _, m := range matches {
+		var n int
+		if _, err := fmt.Sscanf(m[len(prefix):], "%d", &n); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return fmt.Sprintf("%s%03d", prefix, next)
+}
+
+// pruneBackups deletes the oldest rotated files once more than maxBackups
+// exist.  Callers must hold w.mu.
+func (w *RotatingFileLogWriter) pruneBackups() {
+	if w.maxBackups <= 0 {
+		return
+	}
+
+	matches, err := filepath.Glob(w.path + ".*")
+	if err != nil || len(matches) <= w.maxBackups {
+		return
+	}
+
+	sort.Strings(matches)
+	for _, old := range matches[:len(matches)-w.maxBackups] {
+		os.Remove(old)
+	}
+}
+
+// compressBackup gzips path in place and removes the uncompressed original.
+// It runs off the log dispatch goroutine so a rotation never blocks on it.
+func compressBackup(path string) {
+	in, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer in.Close()
+
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		return
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		gz.Close()
+		os.Remove(path + ".gz")
+		return
+	}
+	gz.Close()
+
+	os.Remove(path)
+}
+
+// LogWrite formats rec and appends it to the active file.  The daily trigger
+// rotates before rec is written, when rec falls on a new day; the size and
+// line triggers rotate right after the write that reaches them.
+func (w *RotatingFileLogWriter) LogWrite(rec *LogRecord) {
+	w.write(rec)
+}
+
+// Close closes the active file.
+func (w *RotatingFileLogWriter) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file != nil {
+		w.file.Close()
+		w.file = nil
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func tempLogPath(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "log4go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "test.log")
+}
+
+func TestRotatingFileLogWriterSizeTrigger(t *testing.T) {
+	path := tempLogPath(t)
+	w := NewRotatingFileLogWriter(path).SetRotateSize(40)
+	w.now = func() time.Time { return time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC) }
+
+	for i := 0; i < 5; i++ {
+		w.LogWrite(&LogRecord{Level: INFO, Created: w.now(), Source: "test", Message: "hello world"})
+	}
+	w.Close()
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) == 0 {
+		t.Fatalf("expected at least one rotated backup, found none")
+	}
+}
+
+func TestRotatingFileLogWriterDailyTrigger(t *testing.T) {
+	path := tempLogPath(t)
+	w := NewRotatingFileLogWriter(path).SetRotateDaily(true)
+
+	day1 := time.Date(2020, 1, 2, 23, 59, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Minute)
+
+	w.now = func() time.Time { return day1 }
+	w.LogWrite(&LogRecord{Level: INFO, Created: day1, Source: "test", Message: "one"})
+	w.now = func() time.Time { return day2 }
+	w.LogWrite(&LogRecord{Level: INFO, Created: day2, Source: "test", Message: "two"})
+	w.Close()
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 1 {
+		t.Fatalf("expected exactly one rotated backup across the day boundary, got %d", len(backups))
+	}
+}
+
+func TestRotatingFileLogWriterLinesTrigger(t *testing.T) {
+	path := tempLogPath(t)
+	w := NewRotatingFileLogWriter(path).SetRotateLines(2)
+	now := time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		w.LogWrite(&LogRecord{Level: INFO, Created: now, Source: "test", Message: "line"})
+	}
+	w.Close()
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) < 2 {
+		t.Fatalf("expected at least 2 rotated backups for 5 lines at a 2-line trigger, got %d", len(backups))
+	}
+}
+
+func TestRotatingFileLogWriterMaxBackupsPrunesOldest(t *testing.T) {
+	path := tempLogPath(t)
+	w := NewRotatingFileLogWriter(path).SetRotateLines(1).SetMaxBackups(2)
+	now := time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+
+	for i := 0; i < 6; i++ {
+		w.LogWrite(&LogRecord{Level: INFO, Created: now, Source: "test", Message: "line"})
+	}
+	w.Close()
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Fatalf("expected pruning to leave exactly 2 backups, got %d: %v", len(backups), backups)
+	}
+	want := []string{path + ".2020-01-02.005", path + ".2020-01-02.006"}
+	if backups[0] != want[0] || backups[1] != want[1] {
+		t.Fatalf("expected the 2 newest backups %v to survive pruning, got %v", want, backups)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	minSocketBackoff = 100 * time.Millisecond
+	maxSocketBackoff = 30 * time.Second
+)
+
+// SocketLogWriter ships LogRecords as newline-delimited JSON over a TCP,
+// UDP, or Unix socket connection to a collector such as fluentd, logstash,
+// or vector.  LogWrite writes synchronously and keeps no buffer of its own:
+// a record that arrives while the connection is down, or whose write
+// fails, is dropped and counted by Dropped, not buffered for a later
+// retry.  The dispatching Filter counts such a record as written, so these
+// drops show up in Dropped, not in Logger.Stats.
+// Reconnection is attempted with an exponential backoff capped at 30s.
+type SocketLogWriter struct {
+	proto, addr string
+
+	mu         sync.Mutex
+	conn       net.Conn
+	backoff    time.Duration
+	nextDialAt time.Time
+
+	dropped int64 // access via sync/atomic
+}
+
+// NewSocketLogWriter creates a SocketLogWriter that dials proto ("tcp",
+// "udp", or "unix") at addr.  The connection is established lazily on the
+// first LogWrite, so construction never blocks on the network.
+func NewSocketLogWriter(proto, addr string) *SocketLogWriter {
+	return &SocketLogWriter{proto: proto, addr: addr}
+}
+
+// LogWrite serializes rec as one JSON object and writes it to the
+// connection, dialing (or redialing) first if necessary.  If the connection
+// is down and still within its backoff window, or the dial or write fails,
+// rec is dropped and Dropped is incremented.  Inside the backoff window
+// LogWrite returns without touching the network; the dial and the write
+// themselves carry no timeout of their own.
+func (w *SocketLogWriter) LogWrite(rec *LogRecord) {
+	line, err := socketRecordJSON(rec)
+	if err != nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		if time.Now().Before(w.nextDialAt) {
+			atomic.AddInt64(&w.dropped, 1)
+			return
+		}
+
+		conn, dialErr := net.Dial(w.proto, w.addr)
+		if dialErr != nil {
+			w.scheduleRedial()
+			atomic.AddInt64(&w.dropped, 1)
+			return
+		}
+		w.conn = conn
+		w.backoff = 0
+	}
+
+	if _, writeErr := w.conn.Write(line); writeErr != nil {
+		w.conn.Close()
+		w.conn = nil
+		w.scheduleRedial()
+		atomic.AddInt64(&w.dropped, 1)
+	}
+}
+
+// scheduleRedial backs off the next dial attempt, doubling the previous
+// backoff up to maxSocketBackoff.  Callers must hold w.mu.
+func (w *SocketLogWriter) scheduleRedial() {
+	if w.backoff == 0 {
+		w.backoff = minSocketBackoff
+	} else {
+		w.backoff *= 2
+		if w.backoff > maxSocketBackoff {
+			w.backoff = maxSocketBackoff
+		}
+	}
+	w.nextDialAt = time.Now().Add(w.backoff)
+}
+
+// socketRecordJSON renders rec as one JSON object followed by a newline.
+func socketRecordJSON(rec *LogRecord) ([]byte, error) {
+	obj := jsonRecord{
+		"level":  rec.Level.String(),
+		"ts":     rec.Created.Format(time.RFC3339Nano),
+		"source": rec.Source,
+		"msg":    rec.Message,
+	}
+	for _, f := range rec.Fields {
+		obj[f.Key] = f.Value()
+	}
+
+	line, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// Dropped returns the number of records discarded because the connection
+// was down (dialing, backing off, or mid-write) when LogWrite was called.
+func (w *SocketLogWriter) Dropped() int64 {
+	return atomic.LoadInt64(&w.dropped)
+}
+
+// Close closes the socket, if one is open.
+func (w *SocketLogWriter) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+}
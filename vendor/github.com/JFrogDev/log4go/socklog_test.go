@@ -0,0 +1,91 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSocketLogWriterDeliversOverTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	lines := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		if scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	w := NewSocketLogWriter("tcp", ln.Addr().String())
+	w.LogWrite(&LogRecord{
+		Level:   INFO,
+		Created: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Source:  "test",
+		Message: "hello",
+		Fields:  []Field{String("request_id", "abc")},
+	})
+
+	select {
+	case line := <-lines:
+		var got map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("unmarshal %q: %v", line, err)
+		}
+		if got["msg"] != "hello" || got["request_id"] != "abc" {
+			t.Fatalf("unexpected record: %v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the socket collector to receive a record")
+	}
+
+	w.Close()
+}
+
+func TestSocketLogWriterDropsWhenDisconnected(t *testing.T) {
+	// Nothing is listening on this port, so every dial attempt fails and
+	// LogWrite drops the record instead of blocking.
+	w := NewSocketLogWriter("tcp", "127.0.0.1:1")
+
+	for i := 0; i < 5; i++ {
+		w.LogWrite(&LogRecord{Level: INFO, Created: time.Now(), Source: "test", Message: "msg"})
+	}
+
+	if w.Dropped() != 5 {
+		t.Fatalf("expected all 5 records to be dropped while disconnected, got %d", w.Dropped())
+	}
+}
+
+func TestSocketLogWriterRedialRespectsBackoffWindow(t *testing.T) {
+	// Nothing is listening on this port. The first LogWrite dials and fails
+	// immediately; the next one, coming in before the backoff window
+	// elapses, must be dropped without attempting another dial.
+	w := NewSocketLogWriter("tcp", "127.0.0.1:1")
+
+	w.LogWrite(&LogRecord{Level: INFO, Created: time.Now(), Source: "test", Message: "one"})
+	if w.Dropped() != 1 {
+		t.Fatalf("expected the first write to be dropped on dial failure, got Dropped()=%d", w.Dropped())
+	}
+
+	before := w.nextDialAt
+	w.LogWrite(&LogRecord{Level: INFO, Created: time.Now(), Source: "test", Message: "two"})
+	if w.Dropped() != 2 {
+		t.Fatalf("expected the second write within the backoff window to also be dropped, got Dropped()=%d", w.Dropped())
+	}
+	if !w.nextDialAt.Equal(before) {
+		t.Fatalf("expected a write inside the backoff window not to reschedule the next dial attempt")
+	}
+}